@@ -0,0 +1,135 @@
+// Package convert transcodes between Tauq and other common configuration
+// and serialization formats (JSON, YAML, TOML, CBOR, MessagePack), using
+// Tauq's own JSON bridge as the common pivot format.
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/Epistates/tauq/bindings/go"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies one of the formats Convert can read or write.
+type Format string
+
+// Formats supported out of the box. Additional formats can be added with
+// RegisterFormat.
+const (
+	Tauq    Format = "tauq"
+	JSON    Format = "json"
+	YAML    Format = "yaml"
+	TOML    Format = "toml"
+	CBOR    Format = "cbor"
+	MsgPack Format = "msgpack"
+)
+
+// marshalFunc renders a generic JSON-decoded value (map[string]interface{},
+// []interface{}, or a scalar) in a target format.
+type marshalFunc func(v interface{}) ([]byte, error)
+
+// unmarshalFunc parses src in a source format into a generic value.
+type unmarshalFunc func(src []byte, v interface{}) error
+
+var registryMu sync.RWMutex
+
+var registry = map[Format]struct {
+	marshal   marshalFunc
+	unmarshal unmarshalFunc
+}{
+	Tauq: {
+		marshal:   func(v interface{}) ([]byte, error) { return tauq.Marshal(v) },
+		unmarshal: func(src []byte, v interface{}) error { return tauq.Unmarshal(src, v) },
+	},
+	JSON: {
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+	},
+	YAML: {
+		marshal:   yaml.Marshal,
+		unmarshal: yaml.Unmarshal,
+	},
+	TOML: {
+		marshal:   toml.Marshal,
+		unmarshal: toml.Unmarshal,
+	},
+	CBOR: {
+		marshal: cbor.Marshal,
+		// Decode maps as map[string]interface{} rather than cbor's default
+		// map[interface{}]interface{}, so the result has the same shape as
+		// the other formats' decoded values.
+		unmarshal: func(src []byte, v interface{}) error {
+			dm, err := cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}(nil))}.DecMode()
+			if err != nil {
+				return err
+			}
+			return dm.Unmarshal(src, v)
+		},
+	},
+	MsgPack: {
+		marshal:   msgpack.Marshal,
+		unmarshal: msgpack.Unmarshal,
+	},
+}
+
+// RegisterFormat makes Convert aware of a new format, so downstream users
+// can plug in additional encodings without modifying this package.
+// Registering a name that already exists replaces its handlers. It is
+// safe to call concurrently with itself and with Convert.
+func RegisterFormat(name Format, marshal func(v interface{}) ([]byte, error), unmarshal func(src []byte, v interface{}) error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = struct {
+		marshal   marshalFunc
+		unmarshal unmarshalFunc
+	}{marshal, unmarshal}
+}
+
+// Convert transcodes src from one registered format to another.
+func Convert(src []byte, from, to Format) ([]byte, error) {
+	registryMu.RLock()
+	fromHandlers, ok := registry[from]
+	if !ok {
+		registryMu.RUnlock()
+		return nil, fmt.Errorf("convert: unknown source format %q", from)
+	}
+	toHandlers, ok := registry[to]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("convert: unknown target format %q", to)
+	}
+
+	var v interface{}
+	if err := fromHandlers.unmarshal(src, &v); err != nil {
+		return nil, fmt.Errorf("convert: decode %s: %w", from, err)
+	}
+	out, err := toHandlers.marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("convert: encode %s: %w", to, err)
+	}
+	return out, nil
+}
+
+// ToYAML converts Tauq source to YAML.
+func ToYAML(src []byte) ([]byte, error) { return Convert(src, Tauq, YAML) }
+
+// FromYAML converts YAML to Tauq source.
+func FromYAML(src []byte) ([]byte, error) { return Convert(src, YAML, Tauq) }
+
+// ToTOML converts Tauq source to TOML.
+func ToTOML(src []byte) ([]byte, error) { return Convert(src, Tauq, TOML) }
+
+// FromTOML converts TOML to Tauq source.
+func FromTOML(src []byte) ([]byte, error) { return Convert(src, TOML, Tauq) }
+
+// ToCBOR converts Tauq source to CBOR.
+func ToCBOR(src []byte) ([]byte, error) { return Convert(src, Tauq, CBOR) }
+
+// FromCBOR converts CBOR to Tauq source.
+func FromCBOR(src []byte) ([]byte, error) { return Convert(src, CBOR, Tauq) }
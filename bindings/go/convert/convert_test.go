@@ -0,0 +1,75 @@
+package convert
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestConvertJSONToYAMLRoundTrip(t *testing.T) {
+	src := []byte(`{"name":"Ada","tags":["a","b"]}`)
+
+	yamlOut, err := Convert(src, JSON, YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonOut, err := Convert(yamlOut, YAML, JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(jsonOut) != `{"name":"Ada","tags":["a","b"]}` {
+		t.Errorf("round trip = %s, want original JSON", jsonOut)
+	}
+}
+
+func TestConvertUnknownFormat(t *testing.T) {
+	if _, err := Convert([]byte(`{}`), "bogus", JSON); err == nil {
+		t.Fatal("expected an error for an unknown source format")
+	}
+	if _, err := Convert([]byte(`{}`), JSON, "bogus"); err == nil {
+		t.Fatal("expected an error for an unknown target format")
+	}
+}
+
+func TestRegisterFormatOverridesExisting(t *testing.T) {
+	calls := 0
+	RegisterFormat(JSON,
+		func(v interface{}) ([]byte, error) {
+			calls++
+			return json.Marshal(v)
+		},
+		json.Unmarshal,
+	)
+	defer RegisterFormat(JSON, json.Marshal, json.Unmarshal)
+
+	if _, err := Convert([]byte(`{"a":1}`), JSON, JSON); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("custom marshal called %d times, want 1", calls)
+	}
+}
+
+// TestRegisterFormatConcurrentWithConvert exercises RegisterFormat and
+// Convert from multiple goroutines at once; run with -race to catch a
+// concurrent map write/read on registry.
+func TestRegisterFormatConcurrentWithConvert(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterFormat(MsgPack, msgpack.Marshal, msgpack.Unmarshal)
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := Convert([]byte(`{"a":1}`), JSON, YAML); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
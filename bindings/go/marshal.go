@@ -0,0 +1,414 @@
+package tauq
+
+/*
+#include <stdlib.h>
+
+// format_ir renders an intermediate representation built by walking a Go
+// value (see buildIR) directly to Tauq source, bypassing the JSON round
+// trip used by json_to_tauq_c. input is itself Tauq-shaped JSON so the
+// existing Rust JSON parsing code can be reused to build the IR.
+char* format_ir(const char* input);
+void tauq_free_string(char* s);
+
+*/
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Marshaler is implemented by types that can render themselves as Tauq
+// source directly, instead of going through Marshal's default struct
+// encoding. It is the Tauq analogue of json.Marshaler.
+type Marshaler interface {
+	MarshalTauq() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that can decode a Tauq-encoded
+// representation of themselves. It is the Tauq analogue of
+// json.Unmarshaler.
+type Unmarshaler interface {
+	UnmarshalTauq([]byte) error
+}
+
+// tauqTag describes the parsed contents of a `tauq:"..."` (or, failing
+// that, `json:"..."`) struct tag, mirroring the subset of encoding/json's
+// tag syntax that tauq supports: a field name followed by the
+// "omitempty" option.
+type tauqTag struct {
+	name      string
+	omitempty bool
+	ignore    bool
+}
+
+// parseTag reads the tauq tag for f, falling back to the json tag when no
+// tauq tag is present. A tauq tag always takes precedence over a json tag
+// on the same field.
+func parseTag(f reflect.StructField) tauqTag {
+	raw, ok := f.Tag.Lookup("tauq")
+	if !ok {
+		raw, ok = f.Tag.Lookup("json")
+	}
+	if !ok {
+		return tauqTag{name: f.Name}
+	}
+	if raw == "-" {
+		return tauqTag{ignore: true}
+	}
+
+	parts := strings.Split(raw, ",")
+	tag := tauqTag{name: parts[0]}
+	if tag.name == "" {
+		tag.name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			tag.omitempty = true
+		}
+	}
+	return tag
+}
+
+// rawFragment holds the native Tauq bytes a nested Marshaler produced,
+// keyed by a unique placeholder token that stands in for it in the IR
+// passed to format_ir. format_ir's input is itself Tauq-shaped JSON, so it
+// cannot carry Tauq-native syntax (multiline strings, a deliberate key
+// order, ...) a nested Marshaler might emit; spliceRawFragments patches the
+// rendered output back up afterwards instead.
+type rawFragment struct {
+	token string
+	raw   []byte
+}
+
+// buildIR walks v with reflection and produces a plain interface{} tree
+// (maps, slices and scalars) suitable for json.Marshal, honoring tauq
+// struct tags and the Marshaler interface along the way. Fragments
+// contributed by nested Marshalers are returned separately; see
+// rawFragment.
+func buildIR(v interface{}) (interface{}, []rawFragment, error) {
+	var frags []rawFragment
+	ir, err := buildIRValue(reflect.ValueOf(v), &frags)
+	return ir, frags, err
+}
+
+func buildIRValue(rv reflect.Value, frags *[]rawFragment) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	if rv.CanInterface() {
+		if m, ok := rv.Interface().(Marshaler); ok {
+			b, err := m.MarshalTauq()
+			if err != nil {
+				return nil, err
+			}
+			// Plain ASCII, no control/JSON-escaped characters, so the token
+			// survives json.Marshal(ir) unchanged and can still be found in
+			// format_ir's rendered output.
+			token := fmt.Sprintf("TAUQRAWFRAGMENT%dTAUQRAWFRAGMENT", len(*frags))
+			*frags = append(*frags, rawFragment{token: token, raw: b})
+			return token, nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return buildIRValue(rv.Elem(), frags)
+	case reflect.Struct:
+		out := make(map[string]interface{})
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue // unexported field
+			}
+			tag := parseTag(f)
+			if tag.ignore {
+				continue
+			}
+			fv := rv.Field(i)
+			if tag.omitempty && fv.IsZero() {
+				continue
+			}
+			ir, err := buildIRValue(fv, frags)
+			if err != nil {
+				return nil, err
+			}
+			out[tag.name] = ir
+		}
+		return out, nil
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			ir, err := buildIRValue(rv.Index(i), frags)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = ir
+		}
+		return out, nil
+	case reflect.Map:
+		out := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			ir, err := buildIRValue(rv.MapIndex(key), frags)
+			if err != nil {
+				return nil, err
+			}
+			out[mapKeyString(key)] = ir
+		}
+		return out, nil
+	default:
+		return rv.Interface(), nil
+	}
+}
+
+// spliceRawFragments replaces each fragment's placeholder token in rendered
+// with its native bytes. format_ir renders the token like any other
+// string, so the splice also drops the quote character it wrapped the
+// token in on either side.
+func spliceRawFragments(rendered string, frags []rawFragment) string {
+	for _, f := range frags {
+		idx := strings.Index(rendered, f.token)
+		if idx < 0 {
+			continue
+		}
+		start, end := idx, idx+len(f.token)
+		if start > 0 {
+			start--
+		}
+		if end < len(rendered) {
+			end++
+		}
+		rendered = rendered[:start] + string(f.raw) + rendered[end:]
+	}
+	return rendered
+}
+
+// mapKeyString renders a map key as a string, the same way encoding/json
+// does for the common case of string-keyed maps.
+func mapKeyString(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// mapKeyValue parses k, a map key rendered as a string by mapKeyString on
+// encode, back into keyType, the same way encoding/json decodes integer
+// map keys from their JSON string form.
+func mapKeyValue(k string, keyType reflect.Type) (reflect.Value, error) {
+	v := reflect.New(keyType).Elem()
+	switch keyType.Kind() {
+	case reflect.String:
+		v.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("tauq: invalid map key %q for %s: %w", k, keyType, err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(k, 10, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("tauq: invalid map key %q for %s: %w", k, keyType, err)
+		}
+		v.SetUint(n)
+	default:
+		return reflect.Value{}, fmt.Errorf("tauq: unsupported map key type %s", keyType)
+	}
+	return v, nil
+}
+
+// MarshalTagged returns the Tauq encoding of v, honoring tauq/json struct
+// tags and the Marshaler interface. It renders v through an intermediate
+// representation and format_ir rather than a plain JSON round trip, so
+// types implementing Marshaler can produce Tauq-native forms that JSON
+// cannot express. Marshal is a thin wrapper around it.
+func MarshalTagged(v interface{}) ([]byte, error) {
+	if m, ok := v.(Marshaler); ok {
+		return m.MarshalTauq()
+	}
+
+	ir, frags, err := buildIR(v)
+	if err != nil {
+		return nil, err
+	}
+	irJSON, err := json.Marshal(ir)
+	if err != nil {
+		return nil, err
+	}
+
+	cInput := C.CString(string(irJSON))
+	defer C.free(unsafe.Pointer(cInput))
+
+	cResult := C.format_ir(cInput)
+	if cResult == nil {
+		return nil, errors.New("failed to format tauq ir")
+	}
+	defer C.tauq_free_string(cResult)
+
+	rendered := C.GoString(cResult)
+	if len(frags) > 0 {
+		rendered = spliceRawFragments(rendered, frags)
+	}
+	return []byte(rendered), nil
+}
+
+// UnmarshalTagged parses Tauq-encoded data and stores the result in v,
+// honoring tauq/json struct tags and the Unmarshaler interface. Fields
+// whose own type implements Unmarshaler are decoded through UnmarshalTauq
+// rather than the struct-tag walk, mirroring how buildIR defers to
+// Marshaler on encode.
+func UnmarshalTagged(data []byte, v interface{}) error {
+	if u, ok := v.(Unmarshaler); ok {
+		return u.UnmarshalTauq(data)
+	}
+
+	jsonBytes, err := parseToJSONBytes(data)
+	if err != nil {
+		return err
+	}
+	var ir interface{}
+	if err := json.Unmarshal(jsonBytes, &ir); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("tauq: UnmarshalTagged requires a non-nil pointer")
+	}
+	return setIRValue(ir, rv.Elem())
+}
+
+// setIRValue assigns ir, a value decoded from format_ir's intermediate
+// representation (maps, slices and scalars), into rv, honoring tauq/json
+// struct tags and the Unmarshaler interface the same way buildIRValue
+// honors them on encode.
+func setIRValue(ir interface{}, rv reflect.Value) error {
+	if rv.CanAddr() {
+		if u, ok := rv.Addr().Interface().(Unmarshaler); ok {
+			raw, err := irToTauqBytes(ir)
+			if err != nil {
+				return err
+			}
+			return u.UnmarshalTauq(raw)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if ir == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return setIRValue(ir, rv.Elem())
+	case reflect.Struct:
+		m, ok := ir.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("tauq: cannot unmarshal %T into %s", ir, rv.Type())
+		}
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue // unexported field
+			}
+			tag := parseTag(f)
+			if tag.ignore {
+				continue
+			}
+			fv, ok := m[tag.name]
+			if !ok {
+				continue
+			}
+			if err := setIRValue(fv, rv.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if ir == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		s, ok := ir.([]interface{})
+		if !ok {
+			return fmt.Errorf("tauq: cannot unmarshal %T into %s", ir, rv.Type())
+		}
+		out := reflect.MakeSlice(rv.Type(), len(s), len(s))
+		for i, elem := range s {
+			if err := setIRValue(elem, out.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(out)
+		return nil
+	case reflect.Map:
+		if ir == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		m, ok := ir.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("tauq: cannot unmarshal %T into %s", ir, rv.Type())
+		}
+		out := reflect.MakeMapWithSize(rv.Type(), len(m))
+		for k, val := range m {
+			keyV, err := mapKeyValue(k, rv.Type().Key())
+			if err != nil {
+				return err
+			}
+			elemV := reflect.New(rv.Type().Elem()).Elem()
+			if err := setIRValue(val, elemV); err != nil {
+				return err
+			}
+			out.SetMapIndex(keyV, elemV)
+		}
+		rv.Set(out)
+		return nil
+	case reflect.Interface:
+		if rv.NumMethod() == 0 {
+			if ir == nil {
+				rv.Set(reflect.Zero(rv.Type()))
+				return nil
+			}
+			rv.Set(reflect.ValueOf(ir))
+			return nil
+		}
+		return fmt.Errorf("tauq: cannot unmarshal into %s", rv.Type())
+	default:
+		// Scalars: round-trip through encoding/json to reuse its numeric,
+		// bool and string conversions instead of reimplementing them.
+		b, err := json.Marshal(ir)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, rv.Addr().Interface())
+	}
+}
+
+// irToTauqBytes renders a decoded IR subtree back to Tauq source, so a
+// nested field's Unmarshaler can be invoked with Tauq-encoded bytes the
+// same way it would be at the top level.
+func irToTauqBytes(ir interface{}) ([]byte, error) {
+	b, err := json.Marshal(ir)
+	if err != nil {
+		return nil, err
+	}
+	tauqStr, err := FormatJSON(string(b))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(tauqStr), nil
+}
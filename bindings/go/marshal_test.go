@@ -0,0 +1,118 @@
+package tauq
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type taggedPerson struct {
+	Name string `tauq:"name"`
+	Age  int    `tauq:"age,omitempty"`
+	Note string `tauq:"-"`
+}
+
+func TestBuildIRHonorsTags(t *testing.T) {
+	ir, frags, err := buildIR(taggedPerson{Name: "Ada", Note: "ignored"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frags) != 0 {
+		t.Fatalf("unexpected raw fragments: %v", frags)
+	}
+	m, ok := ir.(map[string]interface{})
+	if !ok {
+		t.Fatalf("buildIR returned %T, want map[string]interface{}", ir)
+	}
+	if m["name"] != "Ada" {
+		t.Errorf("name = %v, want Ada", m["name"])
+	}
+	if _, ok := m["age"]; ok {
+		t.Errorf("age should have been omitted by omitempty, got %v", m["age"])
+	}
+	if _, ok := m["Note"]; ok {
+		t.Errorf("Note should have been ignored by tauq:\"-\"")
+	}
+}
+
+func TestSetIRValueNilIntoInterface(t *testing.T) {
+	var out map[string]interface{}
+	ir := map[string]interface{}{"a": nil, "b": "x"}
+	rv := reflect.ValueOf(&out).Elem()
+	if err := setIRValue(ir, rv); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := out["a"]; !ok || v != nil {
+		t.Errorf(`out["a"] = %v, want nil`, v)
+	}
+	if out["b"] != "x" {
+		t.Errorf(`out["b"] = %v, want "x"`, out["b"])
+	}
+}
+
+func TestSetIRValueNonStringMapKey(t *testing.T) {
+	var out map[int]string
+	ir := map[string]interface{}{"1": "one", "2": "two"}
+	rv := reflect.ValueOf(&out).Elem()
+	if err := setIRValue(ir, rv); err != nil {
+		t.Fatal(err)
+	}
+	if out[1] != "one" || out[2] != "two" {
+		t.Errorf("out = %v, want map[1:one 2:two]", out)
+	}
+}
+
+func TestSetIRValueInvalidMapKey(t *testing.T) {
+	var out map[int]string
+	ir := map[string]interface{}{"not-a-number": "x"}
+	rv := reflect.ValueOf(&out).Elem()
+	if err := setIRValue(ir, rv); err == nil {
+		t.Fatal("expected an error for a non-numeric key into map[int]string, got nil")
+	}
+}
+
+// rawFragmentMarshaler emits Tauq-native syntax (a multiline string) that
+// json.Unmarshal cannot parse, the motivating case for buildIRValue keeping
+// nested Marshaler output as a raw fragment rather than routing it through
+// JSON.
+type rawFragmentMarshaler struct{}
+
+func (rawFragmentMarshaler) MarshalTauq() ([]byte, error) {
+	return []byte("\"\"\"\nmultiline\ntauq string\n\"\"\""), nil
+}
+
+func TestBuildIRNestedMarshalerSurvivesJSONEscaping(t *testing.T) {
+	type wrapper struct {
+		Body rawFragmentMarshaler `tauq:"body"`
+	}
+	ir, frags, err := buildIR(wrapper{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frags) != 1 {
+		t.Fatalf("got %d raw fragments, want 1", len(frags))
+	}
+
+	// The regression this guards against: a token built from bytes that
+	// json.Marshal escapes (e.g. \x01) is no longer findable once it has
+	// passed through json.Marshal(ir), so the splice in MarshalTagged
+	// silently drops the native Tauq output.
+	irJSON, err := json.Marshal(ir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(irJSON), frags[0].token) {
+		t.Fatalf("token %q did not survive json.Marshal(ir): %s", frags[0].token, irJSON)
+	}
+
+	// format_ir would render the token like any other JSON string, quoted;
+	// spliceRawFragments must strip those quotes and splice in the raw
+	// Tauq-native bytes verbatim.
+	rendered := `{"body": "` + frags[0].token + `"}`
+	spliced := spliceRawFragments(rendered, frags)
+	want := `{"body": ` + string(frags[0].raw) + `}`
+	if spliced != want {
+		t.Errorf("spliceRawFragments = %q, want %q", spliced, want)
+	}
+}
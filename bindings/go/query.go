@@ -0,0 +1,128 @@
+package tauq
+
+/*
+#include <stdlib.h>
+
+// tauq_exec_query_opts runs a query with a richer execution budget than
+// tauq_exec_query's single safe_mode flag: a maximum number of recursion
+// / loop iterations, a maximum output size in bytes, a JSON array of
+// allowed function/operator names (or an empty array/"null" for "allow
+// all"), and a set of read-only variable bindings encoded as a JSON
+// object. cancel is polled periodically by the evaluator; setting it to a
+// non-zero value asks the query to stop at the next safe point.
+char* tauq_exec_query_opts(
+	const char* input,
+	bool safe_mode,
+	size_t max_iterations,
+	size_t max_output_bytes,
+	const char* allowed_ops_json,
+	const char* vars_json,
+	const int* cancel
+);
+void tauq_free_string(char* s);
+
+*/
+import "C"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ErrQueryCanceled is returned by ExecContext when ctx is canceled or its
+// deadline is exceeded before the query finishes running.
+var ErrQueryCanceled = errors.New("tauq: query canceled")
+
+// QueryOptions configures a single query execution, replacing the bare
+// safeMode bool accepted by ExecQueryToJSON/Exec with the per-call budget
+// needed to run untrusted or parameterized queries safely.
+type QueryOptions struct {
+	// SafeMode disables operations the query language considers unsafe
+	// (the same flag ExecQueryToJSON's safeMode controls).
+	SafeMode bool
+
+	// MaxIterations caps the number of recursion/loop steps the query may
+	// perform before it is aborted. Zero means unlimited.
+	MaxIterations uint64
+
+	// MaxOutputBytes caps the size of the encoded result. Zero means
+	// unlimited.
+	MaxOutputBytes uint64
+
+	// AllowedOps, when non-empty, restricts the query to this list of
+	// function/operator names. A nil or empty slice allows everything.
+	AllowedOps []string
+
+	// Vars is a read-only set of variable bindings the query may
+	// reference but not mutate, letting callers parameterize a query
+	// instead of interpolating untrusted input into its source.
+	Vars map[string]interface{}
+}
+
+// ExecContext executes a Tauq query against data with the given options,
+// unmarshaling the result into v. The query is canceled if ctx is
+// canceled or its deadline expires before evaluation completes.
+func ExecContext(ctx context.Context, data string, opts QueryOptions, v interface{}) error {
+	jsonStr, err := execQueryOpts(ctx, data, opts)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(jsonStr), v)
+}
+
+// execQueryOpts runs the query described by opts against data and returns
+// its JSON result, honoring ctx's cancellation.
+func execQueryOpts(ctx context.Context, data string, opts QueryOptions) (string, error) {
+	varsJSON, err := json.Marshal(opts.Vars)
+	if err != nil {
+		return "", err
+	}
+	allowedOpsJSON, err := json.Marshal(opts.AllowedOps)
+	if err != nil {
+		return "", err
+	}
+
+	cInput := C.CString(data)
+	defer C.free(unsafe.Pointer(cInput))
+	cAllowed := C.CString(string(allowedOpsJSON))
+	defer C.free(unsafe.Pointer(cAllowed))
+	cVars := C.CString(string(varsJSON))
+	defer C.free(unsafe.Pointer(cVars))
+
+	// cancel is polled from C; setting it asks the evaluator to stop at
+	// its next safe point instead of tearing down the query abruptly.
+	var cancel int32
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			atomic.StoreInt32(&cancel, 1)
+		case <-done:
+		}
+	}()
+
+	cResult := C.tauq_exec_query_opts(
+		cInput,
+		C.bool(opts.SafeMode),
+		C.size_t(opts.MaxIterations),
+		C.size_t(opts.MaxOutputBytes),
+		cAllowed,
+		cVars,
+		(*C.int)(unsafe.Pointer(&cancel)),
+	)
+	if cResult == nil {
+		if ctx.Err() != nil {
+			return "", ErrQueryCanceled
+		}
+		return "", errors.New("failed to execute tauq query")
+	}
+	defer C.tauq_free_string(cResult)
+
+	if err := ctx.Err(); err != nil {
+		return "", ErrQueryCanceled
+	}
+	return C.GoString(cResult), nil
+}
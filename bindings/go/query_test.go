@@ -0,0 +1,29 @@
+package tauq
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExecContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out interface{}
+	err := ExecContext(ctx, `{a: 1}`, QueryOptions{}, &out)
+	if !errors.Is(err, ErrQueryCanceled) {
+		t.Fatalf("ExecContext with a canceled context = %v, want ErrQueryCanceled", err)
+	}
+}
+
+func TestExecContextRunsToCompletion(t *testing.T) {
+	var out map[string]interface{}
+	err := ExecContext(context.Background(), `{a: 1}`, QueryOptions{}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["a"] != float64(1) {
+		t.Errorf(`out["a"] = %v, want 1`, out["a"])
+	}
+}
@@ -0,0 +1,117 @@
+// Package schema validates parsed Tauq values against a JSON Schema
+// (draft 2020-12) or OpenAPI 3.1 schema, translating failures back to
+// line/column positions in the original Tauq source where possible.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Epistates/tauq/bindings/go"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ValidationError describes a single schema validation failure.
+type ValidationError struct {
+	// Path is the JSON pointer (e.g. "/users/0/name") of the offending
+	// value.
+	Path string
+	// Message is the human-readable reason the value failed validation.
+	Message string
+	// Line and Column are the 1-based position of Path in the original
+	// Tauq source, or zero if no span information was available.
+	Line   int
+	Column int
+}
+
+func (e ValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s (line %d, column %d): %s", e.Path, e.Line, e.Column, e.Message)
+}
+
+// Schema is a compiled JSON Schema / OpenAPI 3.1 schema, ready to validate
+// parsed Tauq values.
+type Schema struct {
+	compiled *jsonschema.Schema
+}
+
+// Compile parses and compiles a JSON Schema (draft 2020-12) or
+// OpenAPI 3.1 schema document.
+func Compile(schemaDoc []byte) (*Schema, error) {
+	var schemaValue interface{}
+	if err := json.Unmarshal(schemaDoc, &schemaValue); err != nil {
+		return nil, fmt.Errorf("schema: parse schema: %w", err)
+	}
+
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	const resourceName = "tauq-schema.json"
+	if err := c.AddResource(resourceName, bytes.NewReader(schemaDoc)); err != nil {
+		return nil, fmt.Errorf("schema: add schema resource: %w", err)
+	}
+	compiled, err := c.Compile(resourceName)
+	if err != nil {
+		return nil, fmt.Errorf("schema: compile schema: %w", err)
+	}
+	return &Schema{compiled: compiled}, nil
+}
+
+// Validate checks tauqData, a Tauq-encoded document, against s, returning
+// one ValidationError per failure with source positions filled in where
+// available.
+func (s *Schema) Validate(tauqData []byte) ([]ValidationError, error) {
+	jsonStr, spans, err := tauq.ParseToJSONWithSpans(tauqData)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(jsonStr), &value); err != nil {
+		return nil, err
+	}
+
+	err = s.compiled.Validate(value)
+	if err == nil {
+		return nil, nil
+	}
+	valErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	var out []ValidationError
+	collectCauses(valErr, spans, &out)
+	return out, nil
+}
+
+// collectCauses flattens a jsonschema.ValidationError tree into a flat
+// list of ValidationErrors, resolving each leaf's instance location
+// against spans.
+func collectCauses(ve *jsonschema.ValidationError, spans map[string]tauq.Span, out *[]ValidationError) {
+	if len(ve.Causes) == 0 {
+		pos := spans[ve.InstanceLocation]
+		*out = append(*out, ValidationError{
+			Path:    ve.InstanceLocation,
+			Message: ve.Message,
+			Line:    pos.Line,
+			Column:  pos.Column,
+		})
+		return
+	}
+	for _, cause := range ve.Causes {
+		collectCauses(cause, spans, out)
+	}
+}
+
+// Validate is a convenience wrapper that compiles schemaDoc and validates
+// tauqData against it in one call.
+func Validate(tauqData []byte, schemaDoc []byte) ([]ValidationError, error) {
+	s, err := Compile(schemaDoc)
+	if err != nil {
+		return nil, err
+	}
+	return s.Validate(tauqData)
+}
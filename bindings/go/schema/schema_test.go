@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/Epistates/tauq/bindings/go"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func TestCompileValidSchema(t *testing.T) {
+	_, err := Compile([]byte(`{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompileInvalidJSON(t *testing.T) {
+	if _, err := Compile([]byte(`not json`)); err == nil {
+		t.Fatal("expected an error compiling a malformed schema document")
+	}
+}
+
+func TestValidationErrorString(t *testing.T) {
+	withPos := ValidationError{Path: "/name", Message: "is required", Line: 3, Column: 5}
+	if got, want := withPos.Error(), `/name (line 3, column 5): is required`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	withoutPos := ValidationError{Path: "/name", Message: "is required"}
+	if got, want := withoutPos.Error(), `/name: is required`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCollectCausesResolvesSpans(t *testing.T) {
+	ve := &jsonschema.ValidationError{
+		InstanceLocation: "/name",
+		Message:          "is required",
+	}
+	spans := map[string]tauq.Span{
+		"/name": {Line: 3, Column: 5},
+	}
+
+	var out []ValidationError
+	collectCauses(ve, spans, &out)
+
+	if len(out) != 1 {
+		t.Fatalf("got %d errors, want 1", len(out))
+	}
+	if out[0].Line != 3 || out[0].Column != 5 {
+		t.Errorf("got %+v, want line 3 column 5", out[0])
+	}
+}
+
+func TestCollectCausesFlattensCauseTree(t *testing.T) {
+	ve := &jsonschema.ValidationError{
+		Causes: []*jsonschema.ValidationError{
+			{InstanceLocation: "/a", Message: "bad a"},
+			{InstanceLocation: "/b", Message: "bad b"},
+		},
+	}
+
+	var out []ValidationError
+	collectCauses(ve, nil, &out)
+
+	if len(out) != 2 {
+		t.Fatalf("got %d errors, want 2", len(out))
+	}
+}
@@ -0,0 +1,62 @@
+package tauq
+
+/*
+#include <stdlib.h>
+#include <stdint.h>
+
+typedef struct {
+	uint8_t* ptr;
+	size_t len;
+	size_t cap;
+} tauq_buf;
+
+// tauq_to_json_with_spans behaves like tauq_to_json, except the returned
+// buffer holds a JSON object of the form
+// {"value": <parsed document>, "spans": {"<json pointer>": [line, column], ...}}
+// mapping each JSON pointer in the parsed value back to its 1-based
+// line/column in the original Tauq source.
+tauq_buf tauq_to_json_with_spans(const uint8_t* input, size_t input_len);
+void tauq_free_buf(tauq_buf buf);
+
+*/
+import "C"
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Span is a 1-based line/column position in a Tauq source document.
+type Span struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// spansResult mirrors the JSON object returned by tauq_to_json_with_spans.
+type spansResult struct {
+	Value json.RawMessage   `json:"value"`
+	Spans map[string][2]int `json:"spans"`
+}
+
+// ParseToJSONWithSpans parses a Tauq document like ParseToJSON, additionally
+// returning a map from JSON pointer (e.g. "/users/0/name") to the source
+// position that value came from. This lets callers translate errors
+// reported against the parsed JSON, such as JSON Schema validation
+// failures, back to a line/column in the original Tauq source.
+func ParseToJSONWithSpans(input []byte) (jsonStr string, spans map[string]Span, err error) {
+	buf := C.tauq_to_json_with_spans(bufView(input), C.size_t(len(input)))
+	raw := goBytes(buf)
+	if raw == nil {
+		return "", nil, errors.New("failed to parse tauq")
+	}
+
+	var result spansResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", nil, err
+	}
+
+	spans = make(map[string]Span, len(result.Spans))
+	for ptr, pos := range result.Spans {
+		spans[ptr] = Span{Line: pos[0], Column: pos[1]}
+	}
+	return string(result.Value), spans, nil
+}
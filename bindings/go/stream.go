@@ -0,0 +1,172 @@
+package tauq
+
+/*
+#include <stdlib.h>
+
+// Forward declarations of the incremental parser entry points from Rust.
+// Unlike tauq_to_json, these operate on a long-lived parser handle that is
+// fed chunks of input as they arrive, so a caller never needs the whole
+// document in memory at once.
+void* tauq_parser_new(void);
+void tauq_parser_feed(void* parser, const char* chunk, size_t len);
+char* tauq_parser_next_value(void* parser);
+char* tauq_parser_next_token(void* parser);
+int tauq_parser_more(void* parser);
+void tauq_parser_free(void* parser);
+void tauq_free_string(char* s);
+
+*/
+import "C"
+import (
+	"encoding/json"
+	"io"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+const decoderReadSize = 4096
+
+// Decoder reads and decodes Tauq values from an input stream, the same way
+// json.Decoder does for JSON. It never requires the whole document to be
+// resident in memory: input is fed to the Rust parser in chunks as it is
+// read.
+type Decoder struct {
+	r      io.Reader
+	parser unsafe.Pointer
+	eof    bool
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{r: r, parser: C.tauq_parser_new()}
+	runtime.SetFinalizer(d, (*Decoder).Close)
+	return d
+}
+
+// Close releases the underlying Rust parser handle. It is safe to call
+// Close more than once.
+func (d *Decoder) Close() error {
+	if d.parser == nil {
+		return nil
+	}
+	C.tauq_parser_free(d.parser)
+	d.parser = nil
+	runtime.SetFinalizer(d, nil)
+	return nil
+}
+
+// Decode reads the next Tauq-encoded value from its input and stores it in
+// the value pointed to by v.
+func (d *Decoder) Decode(v interface{}) error {
+	jsonStr, err := d.nextValue()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(jsonStr), v)
+}
+
+// Token returns the next JSON-like token in the input stream, mirroring
+// json.Decoder.Token. It allows callers to walk a large Tauq document
+// without materializing it as a single Go value.
+func (d *Decoder) Token() (json.Token, error) {
+	cResult, err := d.next(parserPollToken)
+	if err != nil {
+		return nil, err
+	}
+	defer C.tauq_free_string(cResult)
+
+	dec := json.NewDecoder(strings.NewReader(C.GoString(cResult)))
+	return dec.Token()
+}
+
+// More reports whether there is another element in the current array or
+// object being parsed, mirroring json.Decoder.More.
+func (d *Decoder) More() bool {
+	return C.tauq_parser_more(d.parser) != 0
+}
+
+// nextValue pulls the next complete top-level value out of the parser,
+// feeding it more input as needed.
+func (d *Decoder) nextValue() (string, error) {
+	cResult, err := d.next(parserPollValue)
+	if err != nil {
+		return "", err
+	}
+	defer C.tauq_free_string(cResult)
+	return C.GoString(cResult), nil
+}
+
+// parserPollKind selects which Rust entry point next polls with, since cgo
+// function references (C.foo) are not ordinary Go func values and cannot be
+// passed or stored as one.
+type parserPollKind int
+
+const (
+	parserPollValue parserPollKind = iota
+	parserPollToken
+)
+
+// next repeatedly polls the parser for the given kind of result, feeding it
+// more bytes from r until it returns a non-nil result or the underlying
+// reader is exhausted.
+func (d *Decoder) next(kind parserPollKind) (*C.char, error) {
+	for {
+		var cResult *C.char
+		switch kind {
+		case parserPollToken:
+			cResult = C.tauq_parser_next_token(d.parser)
+		default:
+			cResult = C.tauq_parser_next_value(d.parser)
+		}
+		if cResult != nil {
+			return cResult, nil
+		}
+		if d.eof {
+			return nil, io.EOF
+		}
+		if err := d.fill(); err != nil {
+			if err == io.EOF {
+				d.eof = true
+				continue
+			}
+			return nil, err
+		}
+	}
+}
+
+// fill reads one chunk from r and feeds it to the Rust parser.
+func (d *Decoder) fill() error {
+	chunk := make([]byte, decoderReadSize)
+	n, err := d.r.Read(chunk)
+	if n > 0 {
+		cChunk := (*C.char)(unsafe.Pointer(&chunk[0]))
+		C.tauq_parser_feed(d.parser, cChunk, C.size_t(n))
+	}
+	return err
+}
+
+// Encoder writes Tauq values to an output stream, the same way json.Encoder
+// does for JSON.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the Tauq encoding of v to the stream, followed by a newline
+// character.
+func (e *Encoder) Encode(v interface{}) error {
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(b); err != nil {
+		return err
+	}
+	_, err = e.w.Write([]byte("\n"))
+	return err
+}
@@ -3,12 +3,29 @@ package tauq
 /*
 #cgo LDFLAGS: -L../../target/release -ltauq
 #include <stdlib.h>
-
-// Forward declarations of C functions from Rust
-char* tauq_to_json(const char* input);
-char* tauq_exec_query(const char* input, bool safe_mode);
-char* tauq_minify(const char* input);
-char* json_to_tauq_c(const char* input);
+#include <stdint.h>
+
+// tauq_buf is a borrowed or owned view over a byte buffer crossing the
+// cgo boundary. Buffers returned by the functions below are owned by the
+// Rust side and must be released with tauq_free_buf exactly once.
+typedef struct {
+	uint8_t* ptr;
+	size_t len;
+	size_t cap;
+} tauq_buf;
+
+// Forward declarations of C functions from Rust. Each takes a
+// pointer+length view of its input instead of a NUL-terminated string, so
+// Go can pass a slice header directly without a copy.
+tauq_buf tauq_to_json(const uint8_t* input, size_t input_len);
+tauq_buf tauq_exec_query(const uint8_t* input, size_t input_len, bool safe_mode);
+tauq_buf tauq_minify(const uint8_t* input, size_t input_len);
+tauq_buf json_to_tauq_c(const uint8_t* input, size_t input_len);
+void tauq_free_buf(tauq_buf buf);
+
+// tauq_free_string frees the NUL-terminated strings still returned by the
+// incremental parser and query-options entry points declared elsewhere in
+// this package.
 void tauq_free_string(char* s);
 
 */
@@ -19,74 +36,92 @@ import (
 	"unsafe"
 )
 
-// Parse parses a Tauq string and returns the JSON string representation
-// (Intermediate step before unmarshaling to Go struct)
-func ParseToJSON(input string) (string, error) {
-	cInput := C.CString(input)
-	defer C.free(unsafe.Pointer(cInput))
+// goBytes copies a Rust-owned tauq_buf into a freshly allocated Go []byte
+// and releases the original buffer. It is the only copy made on the
+// return path, replacing the copy-to-C-string-then-copy-to-Go-string
+// double copy that C.GoString incurred.
+func goBytes(buf C.tauq_buf) []byte {
+	if buf.ptr == nil {
+		return nil
+	}
+	defer C.tauq_free_buf(buf)
+	return C.GoBytes(unsafe.Pointer(buf.ptr), C.int(buf.len))
+}
 
-	cResult := C.tauq_to_json(cInput)
-	if cResult == nil {
-		return "", errors.New("failed to parse tauq")
+// bufView returns a C pointer to data's backing array without copying it.
+// The pointer is only valid for the duration of the cgo call it is passed
+// to; data must be kept alive by the caller until that call returns.
+func bufView(data []byte) *C.uint8_t {
+	if len(data) == 0 {
+		return nil
 	}
-	defer C.tauq_free_string(cResult)
+	return (*C.uint8_t)(unsafe.Pointer(&data[0]))
+}
 
-	return C.GoString(cResult), nil
+// ParseToJSON parses a Tauq document and returns the JSON representation
+// (intermediate step before unmarshaling to a Go struct).
+func ParseToJSON(input string) (string, error) {
+	b, err := parseToJSONBytes([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
 }
 
-// ExecQueryToJSON executes a Tauq Query and returns JSON string
-func ExecQueryToJSON(input string, safeMode bool) (string, error) {
-	cInput := C.CString(input)
-	defer C.free(unsafe.Pointer(cInput))
+// parseToJSONBytes is the []byte-native form of ParseToJSON, used by
+// Unmarshal to avoid the string<->[]byte copy that converting through
+// ParseToJSON's string parameter would otherwise require.
+func parseToJSONBytes(input []byte) ([]byte, error) {
+	buf := C.tauq_to_json(bufView(input), C.size_t(len(input)))
+	result := goBytes(buf)
+	if result == nil {
+		return nil, errors.New("failed to parse tauq")
+	}
+	return result, nil
+}
 
-	cResult := C.tauq_exec_query(cInput, C.bool(safeMode))
-	if cResult == nil {
+// ExecQueryToJSON executes a Tauq query and returns the JSON result.
+func ExecQueryToJSON(input string, safeMode bool) (string, error) {
+	in := []byte(input)
+	buf := C.tauq_exec_query(bufView(in), C.size_t(len(in)), C.bool(safeMode))
+	result := goBytes(buf)
+	if result == nil {
 		return "", errors.New("failed to execute tauq query")
 	}
-	defer C.tauq_free_string(cResult)
-
-	return C.GoString(cResult), nil
+	return string(result), nil
 }
 
-// Minify compresses Tauq source to a single line
+// Minify compresses Tauq source to a single line.
 func Minify(input string) (string, error) {
-	cInput := C.CString(input)
-	defer C.free(unsafe.Pointer(cInput))
-
-	cResult := C.tauq_minify(cInput)
-	if cResult == nil {
+	in := []byte(input)
+	buf := C.tauq_minify(bufView(in), C.size_t(len(in)))
+	result := goBytes(buf)
+	if result == nil {
 		return "", errors.New("failed to minify tauq")
 	}
-	defer C.tauq_free_string(cResult)
-
-	return C.GoString(cResult), nil
+	return string(result), nil
 }
 
-// FormatJSON converts a JSON string to Tauq format
+// FormatJSON converts a JSON string to Tauq format.
 func FormatJSON(inputJSON string) (string, error) {
-	cInput := C.CString(inputJSON)
-	defer C.free(unsafe.Pointer(cInput))
-
-	cResult := C.json_to_tauq_c(cInput)
-	if cResult == nil {
+	in := []byte(inputJSON)
+	buf := C.json_to_tauq_c(bufView(in), C.size_t(len(in)))
+	result := goBytes(buf)
+	if result == nil {
 		return "", errors.New("failed to format json")
 	}
-	defer C.tauq_free_string(cResult)
-
-	return C.GoString(cResult), nil
+	return string(result), nil
 }
 
-// Unmarshal parses Tauq-encoded data and stores the result in the value pointed to by v.
-// It behaves like json.Unmarshal but for Tauq.
+// Unmarshal parses Tauq-encoded data and stores the result in the value
+// pointed to by v. It behaves like json.Unmarshal but for Tauq, and, like
+// UnmarshalTagged, honors tauq/json struct tags and the Unmarshaler
+// interface.
 func Unmarshal(data []byte, v interface{}) error {
-	jsonStr, err := ParseToJSON(string(data))
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal([]byte(jsonStr), v)
+	return UnmarshalTagged(data, v)
 }
 
-// Exec unmarshals the result of a Tauq Query into v.
+// Exec unmarshals the result of a Tauq query into v.
 func Exec(data []byte, safeMode bool, v interface{}) error {
 	jsonStr, err := ExecQueryToJSON(string(data), safeMode)
 	if err != nil {
@@ -95,18 +130,9 @@ func Exec(data []byte, safeMode bool, v interface{}) error {
 	return json.Unmarshal([]byte(jsonStr), v)
 }
 
-// Marshal returns the Tauq encoding of v.
-// It behaves like json.Marshal but returns Tauq.
+// Marshal returns the Tauq encoding of v. It behaves like json.Marshal but
+// returns Tauq, and, like MarshalTagged, honors tauq/json struct tags and
+// the Marshaler interface.
 func Marshal(v interface{}) ([]byte, error) {
-	jsonData, err := json.Marshal(v)
-	if err != nil {
-		return nil, err
-	}
-	
-	tauqStr, err := FormatJSON(string(jsonData))
-	if err != nil {
-		return nil, err
-	}
-	
-	return []byte(tauqStr), nil
-}
\ No newline at end of file
+	return MarshalTagged(v)
+}
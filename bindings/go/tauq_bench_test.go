@@ -0,0 +1,42 @@
+package tauq
+
+import (
+	"strings"
+	"testing"
+)
+
+// genBench builds a Tauq document of roughly the requested size by
+// repeating a small record until the target length is reached.
+func genBench(size int) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for b.Len() < size {
+		b.WriteString(`{name: "item", value: 42, tags: ["a", "b", "c"]},`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func BenchmarkParse1KB(b *testing.B) {
+	input := genBench(1024)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseToJSON(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse1MB(b *testing.B) {
+	input := genBench(1024 * 1024)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseToJSON(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}